@@ -0,0 +1,182 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*************************************/
+
+// resolve which archive format(s) should be produced for a given platform kernel,
+// based on `INPUT_ARCHIVE_FORMAT` (auto|tar.gz|zip|both)
+func resolveArchiveFormats(platformKernel string) []string {
+
+	format := strings.ToLower(strings.TrimSpace(os.Getenv("INPUT_ARCHIVE_FORMAT")))
+	if format == "" {
+		format = "auto"
+	}
+
+	switch format {
+	case "both":
+		return []string{"tar.gz", "zip"}
+	case "zip":
+		return []string{"zip"}
+	case "tar.gz":
+		return []string{"tar.gz"}
+	case "auto":
+		// fall through to the auto-detect logic below
+	default:
+		fmt.Println("Unrecognized INPUT_ARCHIVE_FORMAT value, falling back to \"auto\":", format)
+	}
+
+	if platformKernel == "windows" {
+		return []string{"zip"}
+	}
+	return []string{"tar.gz"}
+}
+
+// create a `.zip` archive in-process, placing every file in `includeFiles` (relative
+// to `baseDir`) under a top-level `dirPrefix/` directory so extraction stays tidy.
+// When `pinnedModTime` is non-nil (reproducible-build mode), entries are written in
+// sorted order and every entry's modification time is overridden to it so repeated
+// runs produce a byte-identical archive.
+func writeZipArchive(zipPath, baseDir, dirPrefix string, includeFiles []string, pinnedModTime *time.Time) error {
+
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		return fmt.Errorf("unable to create zip file: %w", err)
+	}
+	defer zipFile.Close()
+
+	zipWriter := zip.NewWriter(zipFile)
+	defer zipWriter.Close()
+
+	names := append([]string(nil), includeFiles...)
+	if pinnedModTime != nil {
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		if err := addFileToZip(zipWriter, filepath.Join(baseDir, name), filepath.Join(dirPrefix, name), pinnedModTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add a single file to an open `zip.Writer`, preserving its file mode. When
+// `pinnedModTime` is non-nil, the entry's modification time is overridden to it
+// instead of the file's actual `os.FileInfo.ModTime()`.
+func addFileToZip(zipWriter *zip.Writer, srcPath, archivePath string, pinnedModTime *time.Time) error {
+
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("unable to open file for zipping: %w", err)
+	}
+	defer srcFile.Close()
+
+	info, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat file for zipping: %w", err)
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return fmt.Errorf("unable to build zip header: %w", err)
+	}
+	header.Name = filepath.ToSlash(archivePath)
+	header.Method = zip.Deflate
+
+	if pinnedModTime != nil {
+		header.Modified = *pinnedModTime
+	}
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("unable to create zip entry: %w", err)
+	}
+
+	if _, err := io.Copy(writer, srcFile); err != nil {
+		return fmt.Errorf("unable to write zip entry: %w", err)
+	}
+
+	return nil
+}
+
+// create a `.tar.gz` archive in-process for reproducible-build mode: entries are
+// written in sorted order with a fixed uid/gid/uname/gname and `mtime` used for
+// every entry, instead of shelling out to `tar` (which offers no such control),
+// so repeated runs against the same inputs produce a byte-identical archive.
+func writeTarGzArchive(tarGzPath, baseDir, dirPrefix string, includeFiles []string, mtime time.Time) error {
+
+	archiveFile, err := os.Create(tarGzPath)
+	if err != nil {
+		return fmt.Errorf("unable to create tar.gz file: %w", err)
+	}
+	defer archiveFile.Close()
+
+	gzipWriter := gzip.NewWriter(archiveFile)
+	defer gzipWriter.Close()
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	names := append([]string(nil), includeFiles...)
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := addFileToTar(tarWriter, filepath.Join(baseDir, name), filepath.Join(dirPrefix, name), mtime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add a single file to an open `tar.Writer`, preserving its file mode but pinning
+// its modification time and owner metadata for reproducibility
+func addFileToTar(tarWriter *tar.Writer, srcPath, archivePath string, mtime time.Time) error {
+
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("unable to open file for archiving: %w", err)
+	}
+	defer srcFile.Close()
+
+	info, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat file for archiving: %w", err)
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return fmt.Errorf("unable to build tar header: %w", err)
+	}
+	header.Name = filepath.ToSlash(archivePath)
+	header.ModTime = mtime
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uid = 0
+	header.Gid = 0
+	header.Uname = ""
+	header.Gname = ""
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("unable to write tar header: %w", err)
+	}
+
+	if _, err := io.Copy(tarWriter, srcFile); err != nil {
+		return fmt.Errorf("unable to write tar entry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestResolveArchiveFormats(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputFormat    string
+		platformKernel string
+		want           []string
+	}{
+		{name: "auto on linux", inputFormat: "", platformKernel: "linux", want: []string{"tar.gz"}},
+		{name: "auto on windows", inputFormat: "", platformKernel: "windows", want: []string{"zip"}},
+		{name: "explicit zip", inputFormat: "zip", platformKernel: "linux", want: []string{"zip"}},
+		{name: "explicit tar.gz", inputFormat: "tar.gz", platformKernel: "windows", want: []string{"tar.gz"}},
+		{name: "both", inputFormat: "both", platformKernel: "linux", want: []string{"tar.gz", "zip"}},
+		{name: "case-insensitive", inputFormat: "ZIP", platformKernel: "linux", want: []string{"zip"}},
+		// an unrecognized value must fall back to "auto" instead of recursing
+		// forever on the same (still-invalid) env var
+		{name: "unrecognized falls back to auto on linux", inputFormat: "tarball", platformKernel: "linux", want: []string{"tar.gz"}},
+		{name: "unrecognized falls back to auto on windows", inputFormat: "tarball", platformKernel: "windows", want: []string{"zip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("INPUT_ARCHIVE_FORMAT", tt.inputFormat)
+			defer os.Unsetenv("INPUT_ARCHIVE_FORMAT")
+
+			got := resolveArchiveFormats(tt.platformKernel)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("resolveArchiveFormats(%q) = %v, want %v", tt.platformKernel, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("resolveArchiveFormats(%q) = %v, want %v", tt.platformKernel, got, tt.want)
+				}
+			}
+		})
+	}
+}
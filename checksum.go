@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+/*************************************/
+
+// compute the checksum of a file using the given hash constructor, returning
+// it hex-encoded
+func hashFile(path string, newHash func() hash.Hash) (string, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to open file for hashing: %w", err)
+	}
+	defer file.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("unable to hash file: %w", err)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// writeChecksumSidecars computes the SHA256 and MD5 digests of `archivePath`
+// and writes `<archive>.sha256` / `<archive>.md5` sidecar files next to it,
+// in the GNU coreutils `<hex>  <filename>` format. Returns the sha256 digest
+// so it can also be folded into the combined SHA256SUMS manifest.
+func writeChecksumSidecars(archivePath string) (string, error) {
+
+	archiveName := filepath.Base(archivePath)
+
+	sha256Hex, err := hashFile(archivePath, sha256.New)
+	if err != nil {
+		return "", fmt.Errorf("unable to compute sha256 for %s: %w", archiveName, err)
+	}
+	if err := writeChecksumFile(archivePath+".sha256", sha256Hex, archiveName); err != nil {
+		return "", err
+	}
+
+	md5Hex, err := hashFile(archivePath, md5.New)
+	if err != nil {
+		return "", fmt.Errorf("unable to compute md5 for %s: %w", archiveName, err)
+	}
+	if err := writeChecksumFile(archivePath+".md5", md5Hex, archiveName); err != nil {
+		return "", err
+	}
+
+	return sha256Hex, nil
+}
+
+// write a single coreutils-format checksum line (`<hex>  <filename>`) to `path`
+func writeChecksumFile(path, digestHex, fileName string) error {
+	line := fmt.Sprintf("%s  %s\n", digestHex, fileName)
+	if err := os.WriteFile(path, []byte(line), 0644); err != nil {
+		return fmt.Errorf("unable to write checksum file %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeSHA256Sums writes a combined `SHA256SUMS` manifest in `destDirPath`,
+// covering every archive produced across the whole run
+func writeSHA256Sums(destDirPath string, entries map[string]string) error {
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(destDirPath, "SHA256SUMS")
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create SHA256SUMS manifest: %w", err)
+	}
+	defer file.Close()
+
+	fileNames := make([]string, 0, len(entries))
+	for fileName := range entries {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	for _, fileName := range fileNames {
+		if _, err := fmt.Fprintf(file, "%s  %s\n", entries[fileName], fileName); err != nil {
+			return fmt.Errorf("unable to write SHA256SUMS manifest: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteChecksumSidecars(t *testing.T) {
+	dir := t.TempDir()
+
+	archivePath := filepath.Join(dir, "demo-linux-amd64.tar.gz")
+	if err := os.WriteFile(archivePath, []byte("archive contents"), 0644); err != nil {
+		t.Fatalf("unable to write fixture archive: %v", err)
+	}
+
+	sha256Hex, err := writeChecksumSidecars(archivePath)
+	if err != nil {
+		t.Fatalf("writeChecksumSidecars() returned an error: %v", err)
+	}
+
+	wantSHA256, err := hashFile(archivePath, sha256.New)
+	if err != nil {
+		t.Fatalf("hashFile() returned an error: %v", err)
+	}
+	if sha256Hex != wantSHA256 {
+		t.Errorf("writeChecksumSidecars() sha256 = %q, want %q", sha256Hex, wantSHA256)
+	}
+
+	for _, ext := range []string{".sha256", ".md5"} {
+		if _, err := os.Stat(archivePath + ext); err != nil {
+			t.Errorf("expected sidecar %s to exist: %v", ext, err)
+		}
+	}
+}
+
+func TestWriteSHA256Sums(t *testing.T) {
+	dir := t.TempDir()
+
+	entries := map[string]string{
+		"demo-linux-amd64.tar.gz": "aaaa",
+		"demo-windows-amd64.zip":  "bbbb",
+	}
+
+	if err := writeSHA256Sums(dir, entries); err != nil {
+		t.Fatalf("writeSHA256Sums() returned an error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "SHA256SUMS"))
+	if err != nil {
+		t.Fatalf("unable to read SHA256SUMS: %v", err)
+	}
+
+	want := "aaaa  demo-linux-amd64.tar.gz\nbbbb  demo-windows-amd64.zip\n"
+	if string(data) != want {
+		t.Errorf("SHA256SUMS = %q, want %q", string(data), want)
+	}
+}
+
+func TestWriteSHA256SumsSkipsEmptyManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := writeSHA256Sums(dir, map[string]string{}); err != nil {
+		t.Fatalf("writeSHA256Sums() returned an error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "SHA256SUMS")); !os.IsNotExist(err) {
+		t.Errorf("expected no SHA256SUMS file to be written for an empty manifest")
+	}
+}
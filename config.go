@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*************************************/
+
+// Target describes everything needed to build and package a single
+// GOOS/GOARCH combination. It is populated either from an INPUT_CONFIG
+// matrix file or, as a thin adapter, from the legacy comma-separated
+// INPUT_PLATFORMS list.
+type Target struct {
+	GOOS       string            `yaml:"goos" json:"goos"`
+	GOARCH     string            `yaml:"goarch" json:"goarch"`
+	GOARM      string            `yaml:"goarm,omitempty" json:"goarm,omitempty"`
+	GOAMD64    string            `yaml:"goamd64,omitempty" json:"goamd64,omitempty"`
+	CGOEnabled *bool             `yaml:"cgo_enabled,omitempty" json:"cgo_enabled,omitempty"`
+	Env        map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+	Tags       []string          `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Ldflags    string            `yaml:"ldflags,omitempty" json:"ldflags,omitempty"`
+	PreHook    string            `yaml:"pre_hook,omitempty" json:"pre_hook,omitempty"`
+	PostHook   string            `yaml:"post_hook,omitempty" json:"post_hook,omitempty"`
+	ExtraFiles []string          `yaml:"extra_files,omitempty" json:"extra_files,omitempty"`
+}
+
+// buildMatrix is the top-level shape of an INPUT_CONFIG file
+type buildMatrix struct {
+	Targets []Target `yaml:"targets" json:"targets"`
+}
+
+// resolveTargets builds the list of targets to compile, preferring an
+// INPUT_CONFIG matrix file when `configPath` is set and otherwise falling
+// back to the legacy `os/arch,os/arch` INPUT_PLATFORMS list
+func resolveTargets(configPath, platformsCSV string) ([]Target, error) {
+
+	if configPath == "" {
+		return targetsFromPlatforms(platformsCSV), nil
+	}
+
+	targets, err := loadTargetsFromConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load INPUT_CONFIG: %w", err)
+	}
+
+	return targets, nil
+}
+
+// loadTargetsFromConfig reads and parses a YAML or JSON build matrix file,
+// selecting the decoder by file extension (`.json` is treated as JSON,
+// everything else as YAML)
+func loadTargetsFromConfig(configPath string) ([]Target, error) {
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file %s: %w", configPath, err)
+	}
+
+	var matrix buildMatrix
+
+	if strings.ToLower(filepath.Ext(configPath)) == ".json" {
+		if err := json.Unmarshal(data, &matrix); err != nil {
+			return nil, fmt.Errorf("unable to parse config file %s as JSON: %w", configPath, err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &matrix); err != nil {
+			return nil, fmt.Errorf("unable to parse config file %s as YAML: %w", configPath, err)
+		}
+	}
+
+	if len(matrix.Targets) == 0 {
+		return nil, fmt.Errorf("config file %s declares no targets", configPath)
+	}
+
+	return matrix.Targets, nil
+}
+
+// targetsFromPlatforms is the thin adapter that keeps the legacy
+// `INPUT_PLATFORMS=os/arch,os/arch` interface working as a plain GOOS/GOARCH
+// target list
+func targetsFromPlatforms(platformsCSV string) []Target {
+
+	var targets []Target
+
+	for _, platform := range strings.Split(platformsCSV, ",") {
+
+		// split platform by `/` (and clean all whitespaces)
+		platformSpec := strings.Split(strings.ReplaceAll(platform, " ", ""), "/")
+
+		targets = append(targets, Target{
+			GOOS:   platformSpec[0],
+			GOARCH: platformSpec[1],
+		})
+	}
+
+	return targets
+}
+
+// String renders the target as a `goos/goarch` label (matching the format
+// used for the legacy INPUT_PLATFORMS entries), with `goarm`/`goamd64`
+// appended as a further `/v7`-style segment when set, so two targets that
+// only differ by sub-architecture (e.g. `linux/arm` goarm 6 vs. 7) are
+// distinguishable in log prefixes
+func (t Target) String() string {
+	label := fmt.Sprintf("%s/%s", t.GOOS, t.GOARCH)
+	if t.GOARM != "" {
+		label += "/v" + strings.TrimPrefix(t.GOARM, "v")
+	}
+	if t.GOAMD64 != "" {
+		label += "/" + t.GOAMD64
+	}
+	return label
+}
+
+// Slug renders the target as a filesystem-safe identifier (the same
+// information as String, with `/` replaced by `-`), used to build unique
+// build file, archive and installer names per target - including
+// `goarm`/`goamd64` so targets that differ only by sub-architecture don't
+// collide on the same file name
+func (t Target) Slug() string {
+	return strings.ReplaceAll(t.String(), "/", "-")
+}
+
+// boolEnvValue renders a bool as the "0"/"1" string Go toolchain env vars
+// such as CGO_ENABLED expect
+func boolEnvValue(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// runHook executes a target's `pre_hook`/`post_hook` shell command, streaming
+// its output through the prefixed build logger
+func runHook(hook, dir string, env []string, logOut, logErr io.Writer) error {
+
+	hookCmd := exec.Command("sh", "-c", hook)
+	hookCmd.Dir = dir
+	hookCmd.Env = env
+	hookCmd.Stdout = logOut
+	hookCmd.Stderr = logErr
+
+	fmt.Fprintf(logOut, "Running hook: %s\n", hook)
+
+	if err := hookCmd.Run(); err != nil {
+		return fmt.Errorf("hook %q failed: %w", hook, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const yamlMatrixFixture = `
+targets:
+  - goos: linux
+    goarch: arm
+    goarm: "7"
+    cgo_enabled: false
+    env:
+      CC: arm-linux-gnueabihf-gcc
+    tags:
+      - netgo
+      - osusergo
+    ldflags: -X main.variant=pi
+    pre_hook: echo pre
+    post_hook: echo post
+    extra_files:
+      - "*.conf"
+  - goos: darwin
+    goarch: amd64
+`
+
+const jsonMatrixFixture = `{
+  "targets": [
+    {
+      "goos": "windows",
+      "goarch": "amd64",
+      "goamd64": "v3",
+      "cgo_enabled": true,
+      "tags": ["osusergo"],
+      "ldflags": "-X main.variant=win"
+    }
+  ]
+}`
+
+func TestLoadTargetsFromConfigYAML(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "matrix.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlMatrixFixture), 0644); err != nil {
+		t.Fatalf("unable to write fixture: %v", err)
+	}
+
+	targets, err := loadTargetsFromConfig(configPath)
+	if err != nil {
+		t.Fatalf("loadTargetsFromConfig() returned an error: %v", err)
+	}
+
+	if len(targets) != 2 {
+		t.Fatalf("loadTargetsFromConfig() returned %d targets, want 2", len(targets))
+	}
+
+	arm := targets[0]
+	if arm.GOOS != "linux" || arm.GOARCH != "arm" || arm.GOARM != "7" {
+		t.Errorf("targets[0] goos/goarch/goarm = %q/%q/%q, want linux/arm/7", arm.GOOS, arm.GOARCH, arm.GOARM)
+	}
+	if arm.CGOEnabled == nil || *arm.CGOEnabled != false {
+		t.Errorf("targets[0].CGOEnabled = %v, want pointer to false", arm.CGOEnabled)
+	}
+	if arm.Env["CC"] != "arm-linux-gnueabihf-gcc" {
+		t.Errorf("targets[0].Env[CC] = %q, want arm-linux-gnueabihf-gcc", arm.Env["CC"])
+	}
+	if len(arm.Tags) != 2 || arm.Tags[0] != "netgo" || arm.Tags[1] != "osusergo" {
+		t.Errorf("targets[0].Tags = %v, want [netgo osusergo]", arm.Tags)
+	}
+	if arm.Ldflags != "-X main.variant=pi" {
+		t.Errorf("targets[0].Ldflags = %q, want -X main.variant=pi", arm.Ldflags)
+	}
+	if arm.PreHook != "echo pre" || arm.PostHook != "echo post" {
+		t.Errorf("targets[0] pre_hook/post_hook = %q/%q, want echo pre/echo post", arm.PreHook, arm.PostHook)
+	}
+	if len(arm.ExtraFiles) != 1 || arm.ExtraFiles[0] != "*.conf" {
+		t.Errorf("targets[0].ExtraFiles = %v, want [*.conf]", arm.ExtraFiles)
+	}
+
+	darwin := targets[1]
+	if darwin.GOOS != "darwin" || darwin.GOARCH != "amd64" {
+		t.Errorf("targets[1] goos/goarch = %q/%q, want darwin/amd64", darwin.GOOS, darwin.GOARCH)
+	}
+}
+
+func TestLoadTargetsFromConfigJSON(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "matrix.json")
+	if err := os.WriteFile(configPath, []byte(jsonMatrixFixture), 0644); err != nil {
+		t.Fatalf("unable to write fixture: %v", err)
+	}
+
+	targets, err := loadTargetsFromConfig(configPath)
+	if err != nil {
+		t.Fatalf("loadTargetsFromConfig() returned an error: %v", err)
+	}
+
+	if len(targets) != 1 {
+		t.Fatalf("loadTargetsFromConfig() returned %d targets, want 1", len(targets))
+	}
+
+	win := targets[0]
+	if win.GOOS != "windows" || win.GOARCH != "amd64" || win.GOAMD64 != "v3" {
+		t.Errorf("targets[0] goos/goarch/goamd64 = %q/%q/%q, want windows/amd64/v3", win.GOOS, win.GOARCH, win.GOAMD64)
+	}
+	if win.CGOEnabled == nil || *win.CGOEnabled != true {
+		t.Errorf("targets[0].CGOEnabled = %v, want pointer to true", win.CGOEnabled)
+	}
+	if len(win.Tags) != 1 || win.Tags[0] != "osusergo" {
+		t.Errorf("targets[0].Tags = %v, want [osusergo]", win.Tags)
+	}
+	if win.Ldflags != "-X main.variant=win" {
+		t.Errorf("targets[0].Ldflags = %q, want -X main.variant=win", win.Ldflags)
+	}
+}
+
+func TestLoadTargetsFromConfigRejectsEmptyMatrix(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "empty.yaml")
+	if err := os.WriteFile(configPath, []byte("targets: []\n"), 0644); err != nil {
+		t.Fatalf("unable to write fixture: %v", err)
+	}
+
+	if _, err := loadTargetsFromConfig(configPath); err == nil {
+		t.Fatal("loadTargetsFromConfig() with no targets returned no error, want one")
+	}
+}
+
+func TestResolveTargetsLoadsConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "matrix.json")
+	if err := os.WriteFile(configPath, []byte(jsonMatrixFixture), 0644); err != nil {
+		t.Fatalf("unable to write fixture: %v", err)
+	}
+
+	targets, err := resolveTargets(configPath, "this-should-be-ignored")
+	if err != nil {
+		t.Fatalf("resolveTargets() returned an error: %v", err)
+	}
+
+	if len(targets) != 1 || targets[0].GOOS != "windows" {
+		t.Fatalf("resolveTargets() = %+v, want the windows target from the config file", targets)
+	}
+}
+
+func TestTargetStringAndSlug(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     Target
+		wantString string
+		wantSlug   string
+	}{
+		{
+			name:       "plain target",
+			target:     Target{GOOS: "linux", GOARCH: "amd64"},
+			wantString: "linux/amd64",
+			wantSlug:   "linux-amd64",
+		},
+		{
+			name:       "goarm",
+			target:     Target{GOOS: "linux", GOARCH: "arm", GOARM: "7"},
+			wantString: "linux/arm/v7",
+			wantSlug:   "linux-arm-v7",
+		},
+		{
+			name:       "goamd64",
+			target:     Target{GOOS: "linux", GOARCH: "amd64", GOAMD64: "v3"},
+			wantString: "linux/amd64/v3",
+			wantSlug:   "linux-amd64-v3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+			if got := tt.target.Slug(); got != tt.wantSlug {
+				t.Errorf("Slug() = %q, want %q", got, tt.wantSlug)
+			}
+		})
+	}
+}
+
+// two targets differing only by GOARM must produce distinct slugs, otherwise
+// the build/archive/staging file names they're derived from collide when
+// both are built by the same (concurrent) run
+func TestTargetSlugDistinguishesGOARMVariants(t *testing.T) {
+	armv6 := Target{GOOS: "linux", GOARCH: "arm", GOARM: "6"}
+	armv7 := Target{GOOS: "linux", GOARCH: "arm", GOARM: "7"}
+
+	if armv6.Slug() == armv7.Slug() {
+		t.Fatalf("targets differing only by GOARM produced the same slug: %q", armv6.Slug())
+	}
+}
+
+func TestTargetsFromPlatforms(t *testing.T) {
+	targets := targetsFromPlatforms("linux/amd64, windows/386")
+
+	want := []Target{
+		{GOOS: "linux", GOARCH: "amd64"},
+		{GOOS: "windows", GOARCH: "386"},
+	}
+
+	if len(targets) != len(want) {
+		t.Fatalf("targetsFromPlatforms() returned %d targets, want %d", len(targets), len(want))
+	}
+	for i := range want {
+		if targets[i].GOOS != want[i].GOOS || targets[i].GOARCH != want[i].GOARCH {
+			t.Errorf("targetsFromPlatforms()[%d] = %+v, want %+v", i, targets[i], want[i])
+		}
+	}
+}
+
+func TestResolveTargetsFallsBackToPlatforms(t *testing.T) {
+	targets, err := resolveTargets("", "darwin/arm64")
+	if err != nil {
+		t.Fatalf("resolveTargets() returned an error: %v", err)
+	}
+
+	want := Target{GOOS: "darwin", GOARCH: "arm64"}
+	if len(targets) != 1 || targets[0].GOOS != want.GOOS || targets[0].GOARCH != want.GOARCH {
+		t.Fatalf("resolveTargets() = %+v, want [%+v]", targets, want)
+	}
+}
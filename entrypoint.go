@@ -1,12 +1,20 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/joraff/go-cross-build/internal/upload"
 )
 
 /*************************************/
@@ -30,29 +38,62 @@ func fileExists(path string) bool {
 }
 
 // copy file using `cp` command
-func copyFile(src, dest string) {
+func copyFile(src, dest string) error {
 	if err := exec.Command("cp", src, dest).Run(); err != nil {
-		fmt.Println("An error occurred during copy operation:", src, "=>", dest)
-		os.Exit(1)
+		return fmt.Errorf("copy operation failed (%s => %s): %w", src, dest, err)
 	}
+	return nil
+}
+
+// resolve the worker pool size from `INPUT_PARALLELISM`, defaulting to the
+// number of available CPUs
+func resolveParallelism() int {
+
+	raw := strings.TrimSpace(os.Getenv("INPUT_PARALLELISM"))
+	if raw == "" {
+		return runtime.NumCPU()
+	}
+
+	parallelism, err := strconv.Atoi(raw)
+	if err != nil || parallelism < 1 {
+		fmt.Println("Invalid INPUT_PARALLELISM value, falling back to the number of available CPUs:", raw)
+		return runtime.NumCPU()
+	}
+
+	return parallelism
 }
 
 /*************************************/
 
-// build the package for a platform
-func build(packageName, destDir string, platform map[string]string, ldflags string, compress bool) {
+// build the package for a target, streaming the subprocess' stdout/stderr
+// through a `[os/arch]`-prefixed logger so concurrent builds stay readable.
+// Returns the sha256 digest of every archive produced, keyed by file name,
+// so the caller can fold them into a combined SHA256SUMS manifest.
+func build(packageName, destDir string, target Target, baseLdflags string, compress bool, logOut, logErr io.Writer) (map[string]string, error) {
+
+	archiveDigests := make(map[string]string)
 
 	// platform config
-	platformKernel := platform["kernel"]
-	platformArch := platform["arch"]
+	platformKernel := target.GOOS
+	platformArch := target.GOARCH
+
+	// prefix used when logging output from this platform's build
+	logPrefix := target.String()
 
 	// binary executable file path
 	inputName := os.Getenv("INPUT_NAME")
 
-	// build file name (same as the `inputName` if compression is enabled)
-	buildFileName := fmt.Sprintf("%s-%s-%s", inputName, platformKernel, platformArch)
+	// build file name: always unique per target (even when compression is
+	// enabled) since every target's build runs concurrently against the same
+	// `destDirPath` - a name shared across targets (e.g. plain `inputName`)
+	// would mean two workers writing their `go build -o` output to the same
+	// path at once
+	buildFileName := fmt.Sprintf("%s-%s", inputName, target.Slug())
 	if compress {
-		buildFileName = inputName
+		// `.build` keeps this on-disk, transient name distinct from `dirPrefix`
+		// below (same `inputName-slug` format), so staging the archive doesn't
+		// try to create a directory over the freshly built binary
+		buildFileName += ".build"
 	}
 
 	// append `.exe` file-extension for windows
@@ -60,6 +101,17 @@ func build(packageName, destDir string, platform map[string]string, ldflags stri
 		buildFileName += ".exe"
 	}
 
+	// name the build file is given once it's staged into the archive (same as
+	// `inputName` when compression is enabled, matching the pre-worker-pool
+	// archive layout)
+	archiveFileName := buildFileName
+	if compress {
+		archiveFileName = inputName
+		if platformKernel == "windows" {
+			archiveFileName += ".exe"
+		}
+	}
+
 	// workspace directory
 	workspaceDir := os.Getenv("GITHUB_WORKSPACE")
 
@@ -79,103 +131,257 @@ func build(packageName, destDir string, platform map[string]string, ldflags stri
 
 	/*------------*/
 
+	// per-target ldflags (from an INPUT_CONFIG matrix entry) are appended after
+	// the action-wide INPUT_LDFLAGS
+	ldflags := strings.TrimSpace(strings.TrimSpace(baseLdflags) + " " + target.Ldflags)
+
+	// reproducible-build mode: strip local paths and build IDs from the binary so
+	// the same inputs always produce the same bytes
+	reproducible := reproducibleBuildEnabled()
+	if reproducible {
+		ldflags = reproducibleLdflags(ldflags)
+	}
+
 	// command-line options for the `go build` command
-	buildOptions := []string{"build", "-buildmode", "exe", "-ldflags", ldflags, "-o", buildFilePath, packagePath}
+	buildOptions := []string{"build"}
+	if reproducible {
+		buildOptions = append(buildOptions, "-trimpath")
+	}
+	if len(target.Tags) > 0 {
+		buildOptions = append(buildOptions, "-tags", strings.Join(target.Tags, ","))
+	}
+	buildOptions = append(buildOptions, "-buildmode", "exe", "-ldflags", ldflags, "-o", buildFilePath, packagePath)
 
 	// generate `go build` command
 	buildCmd := exec.Command("go", buildOptions...)
 
-	// set environment variables
-	buildCmd.Env = append(os.Environ(), []string{
+	// set environment variables (SOURCE_DATE_EPOCH, when reproducible-build mode is
+	// on, is already present in os.Environ() - main sets it once for the whole run)
+	buildEnv := append(os.Environ(), []string{
 		fmt.Sprintf("GOOS=%s", platformKernel),
 		fmt.Sprintf("GOARCH=%s", platformArch),
 	}...)
+	if target.GOARM != "" {
+		buildEnv = append(buildEnv, fmt.Sprintf("GOARM=%s", target.GOARM))
+	}
+	if target.GOAMD64 != "" {
+		buildEnv = append(buildEnv, fmt.Sprintf("GOAMD64=%s", target.GOAMD64))
+	}
+	if target.CGOEnabled != nil {
+		buildEnv = append(buildEnv, fmt.Sprintf("CGO_ENABLED=%s", boolEnvValue(*target.CGOEnabled)))
+	}
+	for key, value := range target.Env {
+		buildEnv = append(buildEnv, fmt.Sprintf("%s=%s", key, value))
+	}
+	buildCmd.Env = buildEnv
+
+	// stream the subprocess' stdout/stderr through the prefixed logger
+	buildCmd.Stdout = logOut
+	buildCmd.Stderr = logErr
+
+	// run the target's `pre_hook`, if any, before compiling
+	if target.PreHook != "" {
+		if err := runHook(target.PreHook, workspaceDir, buildEnv, logOut, logErr); err != nil {
+			return nil, fmt.Errorf("[%s] pre_hook failed: %w", logPrefix, err)
+		}
+	}
 
 	// execute `go build` command
-	fmt.Println("Creating a build using :", buildCmd.String())
-	if output, err := buildCmd.Output(); err != nil {
-		fmt.Println("An error occurred during build:", err)
-		os.Exit(1)
-	} else {
-		fmt.Printf("%s\n", output)
+	fmt.Fprintf(logOut, "[%s] Creating a build using : %s\n", logPrefix, buildCmd.String())
+	if err := buildCmd.Run(); err != nil {
+		return nil, fmt.Errorf("[%s] build failed: %w", logPrefix, err)
+	}
+
+	// run the target's `post_hook`, if any, after compiling
+	if target.PostHook != "" {
+		if err := runHook(target.PostHook, workspaceDir, buildEnv, logOut, logErr); err != nil {
+			return nil, fmt.Errorf("[%s] post_hook failed: %w", logPrefix, err)
+		}
+	}
+
+	// optionally wrap the binary in an OS-native installer (darwin .pkg / windows
+	// .msi); this has to happen before the archive step below, which deletes the
+	// plain build file once it has been packed into the archive
+	if err := buildInstaller(target, inputName, buildFilePath, destDirPath, logOut, logErr); err != nil {
+		return nil, fmt.Errorf("[%s] installer generation failed: %w", logPrefix, err)
 	}
 
 	/*------------------------------*/
 
-	// create a compressed `.tar.gz` file
+	// create the archive(s) for this platform
 	if compress {
 
-		// compressed gzip file name
-		gzFileName := fmt.Sprintf("%s-%s-%s.tar.gz", inputName, platformKernel, platformArch)
+		// top-level directory every archive entry is nested under, so extraction is tidy
+		dirPrefix := fmt.Sprintf("%s-%s", inputName, target.Slug())
 
 		/*------------*/
 
-		// file to compress (default: build file)
-		includeFiles := []string{buildFileName}
+		// stage the archive contents under `dirPrefix/` so both the `tar` command and
+		// the in-process zip writer produce the same tidy top-level directory. Every
+		// file is copied straight into this per-target directory rather than through
+		// `destDirPath` first - targets build concurrently, and a shared intermediate
+		// path would let one target's cleanup race another target still copying into it.
+		stageDir := filepath.Join(destDirPath, dirPrefix)
+		if err := os.MkdirAll(stageDir, 0755); err != nil {
+			return nil, fmt.Errorf("[%s] unable to stage archive contents: %w", logPrefix, err)
+		}
+
+		// file to archive (default: build file)
+		includeFiles := []string{archiveFileName}
+		if err := copyFile(buildFilePath, filepath.Join(stageDir, archiveFileName)); err != nil {
+			return nil, fmt.Errorf("[%s] %w", logPrefix, err)
+		}
 
-		// copy "README.md" file inside destination directory
+		// copy "README.md" file into the staged archive contents
 		if fileExists("README.md") {
-			copyFile("README.md", filepath.Join(destDirPath, "README.md"))
+			if err := copyFile("README.md", filepath.Join(stageDir, "README.md")); err != nil {
+				return nil, fmt.Errorf("[%s] %w", logPrefix, err)
+			}
 			includeFiles = append(includeFiles, "README.md")
 		}
 
-		// copy "LICENSE" file inside destination directory
+		// copy "LICENSE" file into the staged archive contents
 		if fileExists("LICENSE") {
-			copyFile("LICENSE", filepath.Join(destDirPath, "LICENSE"))
+			if err := copyFile("LICENSE", filepath.Join(stageDir, "LICENSE")); err != nil {
+				return nil, fmt.Errorf("[%s] %w", logPrefix, err)
+			}
 			includeFiles = append(includeFiles, "LICENSE")
 		}
 
-		/*------------*/
-
-		// command-line options for the `tar` command
-		tarOptions := append([]string{"-cvzf", gzFileName}, includeFiles...)
+		// copy files matched by the target's `extra_files` globs into the staged
+		// archive contents
+		for _, pattern := range target.ExtraFiles {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("[%s] invalid extra_files pattern %q: %w", logPrefix, pattern, err)
+			}
+			for _, match := range matches {
+				name := filepath.Base(match)
+				if err := copyFile(match, filepath.Join(stageDir, name)); err != nil {
+					return nil, fmt.Errorf("[%s] %w", logPrefix, err)
+				}
+				includeFiles = append(includeFiles, name)
+			}
+		}
 
-		// generate `tar` command
-		tarCmd := exec.Command("tar", tarOptions...)
+		/*------------*/
 
-		// set working directory for the command
-		tarCmd.Dir = destDirPath
+		// in reproducible-build mode, every archive entry's modification time is
+		// pinned to the run's SOURCE_DATE_EPOCH so repeated runs produce byte-identical
+		// archives
+		var pinnedModTime *time.Time
+		if reproducible {
+			epoch := resolveSourceDateEpoch()
+			pinnedModTime = &epoch
+		}
 
-		// execute `tar` command
-		fmt.Println("Compressing build file using:", tarCmd.String())
-		if err := tarCmd.Run(); err != nil {
-			fmt.Println("An error occurred during compression:", err)
-			os.Exit(1)
+		// produce every archive format requested for this platform (`auto` picks
+		// `.zip` for windows and `.tar.gz` everywhere else; `both` produces both)
+		for _, format := range resolveArchiveFormats(platformKernel) {
+
+			var archivePath string
+
+			switch format {
+
+			case "tar.gz":
+				gzFileName := fmt.Sprintf("%s-%s.tar.gz", inputName, target.Slug())
+				archivePath = filepath.Join(destDirPath, gzFileName)
+
+				if reproducible {
+					fmt.Fprintf(logOut, "[%s] Creating reproducible tar.gz archive: %s\n", logPrefix, archivePath)
+					if err := writeTarGzArchive(archivePath, stageDir, dirPrefix, includeFiles, *pinnedModTime); err != nil {
+						return nil, fmt.Errorf("[%s] compression failed: %w", logPrefix, err)
+					}
+				} else {
+					tarCmd := exec.Command("tar", "-cvzf", gzFileName, dirPrefix)
+					tarCmd.Dir = destDirPath
+					tarCmd.Stdout = logOut
+					tarCmd.Stderr = logErr
+
+					fmt.Fprintf(logOut, "[%s] Compressing build file using: %s\n", logPrefix, tarCmd.String())
+					if err := tarCmd.Run(); err != nil {
+						return nil, fmt.Errorf("[%s] compression failed: %w", logPrefix, err)
+					}
+				}
+
+			case "zip":
+				zipFileName := fmt.Sprintf("%s-%s.zip", inputName, target.Slug())
+				archivePath = filepath.Join(destDirPath, zipFileName)
+
+				fmt.Fprintf(logOut, "[%s] Creating zip archive: %s\n", logPrefix, archivePath)
+				if err := writeZipArchive(archivePath, stageDir, dirPrefix, includeFiles, pinnedModTime); err != nil {
+					return nil, fmt.Errorf("[%s] zip creation failed: %w", logPrefix, err)
+				}
+			}
+
+			// generate the sha256/md5 sidecar files for this archive, and record
+			// its sha256 digest for the combined SHA256SUMS manifest
+			fmt.Fprintf(logOut, "[%s] Generating checksums for: %s\n", logPrefix, archivePath)
+			sha256Hex, err := writeChecksumSidecars(archivePath)
+			if err != nil {
+				return nil, fmt.Errorf("[%s] %w", logPrefix, err)
+			}
+			archiveDigests[filepath.Base(archivePath)] = sha256Hex
 		}
 
 		/*------------*/
 
-		// generate cleanup command
-		cleanCmd := exec.Command("rm", append([]string{"-f"}, includeFiles...)...)
-
-		// set working directory for the command
-		cleanCmd.Dir = destDirPath
+		// clean up the staged files (README/LICENSE/extra_files copies and the
+		// build file, all of which only ever lived under the per-target
+		// `stageDir`) and the now-redundant on-disk build file, already packed
+		// into the archive(s) above
+		if err := os.RemoveAll(stageDir); err != nil {
+			return nil, fmt.Errorf("[%s] cleanup failed: %w", logPrefix, err)
+		}
 
-		// start cleanup process
-		fmt.Println("Performing cleanup operation using:", cleanCmd.String())
-		if err := cleanCmd.Run(); err != nil {
-			fmt.Println("An error occurred during cleaup:", err)
-			os.Exit(1)
+		fmt.Fprintf(logOut, "[%s] Performing cleanup operation on: %s\n", logPrefix, buildFilePath)
+		if err := os.Remove(buildFilePath); err != nil {
+			return nil, fmt.Errorf("[%s] cleanup failed: %w", logPrefix, err)
 		}
+	}
 
-		// md5FileName := fmt.Sprintf("%s-%s-%s.tar.gz.md5", inputName, platformKernel, platformArch)
+	return archiveDigests, nil
+}
 
-		// md5Cmd := exec.Command("/bin/sh", "-c", fmt.Sprintf("'pwd && md5sum %s | cut -c -32 > %s'", gzFileName, md5FileName))
-		md5Cmd := exec.Command("/bin/sh", "-c", fmt.Sprintf("'pwd && ls -la'"))
-		md5Cmd.Dir = destDirPath
+// uploadArtifacts pushes every archive recorded in `allDigests` (plus its
+// `.sha256`/`.md5` sidecars) to the S3-compatible bucket configured via
+// INPUT_UPLOAD_*, and writes a `release.json` index of the results
+func uploadArtifacts(destDirPath string, allDigests map[string]string) error {
 
-		var outb, errb bytes.Buffer
-		md5Cmd.Stdout = &outb
-		md5Cmd.Stderr = &errb
-		fmt.Println("Create md5 checksum file:", md5Cmd.String())
-		if err := md5Cmd.Run(); err != nil {
-			fmt.Println("An error occurred during md5 creation:", err)
-			fmt.Printf("md5 output: %s", errb.String())
-			os.Exit(1)
-		}
-		fmt.Printf("md5 output: %s", outb.String())
+	cfg := upload.ConfigFromEnv()
 
+	project := strings.TrimSpace(os.Getenv("INPUT_UPLOAD_PROJECT"))
+	if project == "" {
+		project = os.Getenv("INPUT_NAME")
 	}
+
+	version := strings.TrimSpace(os.Getenv("INPUT_UPLOAD_VERSION"))
+	if version == "" {
+		version = os.Getenv("GITHUB_REF_NAME")
+	}
+	if version == "" {
+		version = "latest"
+	}
+
+	fileNames := make([]string, 0, len(allDigests))
+	for fileName := range allDigests {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	var artifacts []upload.Artifact
+	for _, fileName := range fileNames {
+		archivePath := filepath.Join(destDirPath, fileName)
+		artifacts = append(artifacts,
+			upload.Artifact{Path: archivePath, SHA256: allDigests[fileName]},
+			upload.Artifact{Path: archivePath + ".sha256"},
+			upload.Artifact{Path: archivePath + ".md5"},
+		)
+	}
+
+	fmt.Println("Uploading", len(artifacts), "artifact(s) to", cfg.Bucket)
+
+	return upload.Run(context.Background(), cfg, project, version, artifacts, destDirPath, os.Stdout)
 }
 
 /*************************************/
@@ -184,6 +390,7 @@ func main() {
 
 	// get input variables from action
 	inputPlatforms := os.Getenv("INPUT_PLATFORMS")
+	inputConfig := os.Getenv("INPUT_CONFIG")
 	inputPackage := os.Getenv("INPUT_PACKAGE")
 	inputCompress := os.Getenv("INPUT_COMPRESS")
 	inputDest := os.Getenv("INPUT_DEST")
@@ -195,8 +402,20 @@ func main() {
 	// destination directory
 	destDir := strings.ReplaceAll(inputDest, " ", "")
 
-	// split platform names by comma (`,`)
-	platforms := strings.Split(inputPlatforms, ",")
+	// resolve the build matrix: an INPUT_CONFIG file takes precedence, falling
+	// back to the legacy comma-separated INPUT_PLATFORMS list
+	targets, err := resolveTargets(inputConfig, inputPlatforms)
+	if err != nil {
+		fmt.Println("An error occurred while resolving the build matrix:", err)
+		os.Exit(1)
+	}
+
+	// in reproducible-build mode, pin SOURCE_DATE_EPOCH once for the whole run so
+	// every platform built concurrently agrees on the same timestamp
+	if reproducibleBuildEnabled() {
+		epoch := resolveSourceDateEpoch()
+		os.Setenv("SOURCE_DATE_EPOCH", strconv.FormatInt(epoch.Unix(), 10))
+	}
 
 	// should compress build file
 	compress := false
@@ -204,20 +423,88 @@ func main() {
 		compress = true
 	}
 
-	// for each platform, execute `build` function
-	for _, platform := range platforms {
+	// run `build` for every target concurrently, bounded by a worker pool sized
+	// from `INPUT_PARALLELISM` (defaulting to the number of available CPUs)
+	parallelism := resolveParallelism()
+	fmt.Println("Building", len(targets), "target(s) with parallelism of", parallelism)
+
+	targetCh := make(chan Target)
+	errCh := make(chan error, len(targets))
+	digestsCh := make(chan map[string]string, len(targets))
+
+	// stdout/stderr for all workers are serialized through this mutex so
+	// prefixed lines from concurrent builds don't interleave mid-line
+	var logMu sync.Mutex
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range targetCh {
+
+				logOut := newPrefixWriter(target.String(), os.Stdout, &logMu)
+				logErr := newPrefixWriter(target.String(), os.Stderr, &logMu)
+
+				digests, err := build(packageName, destDir, target, inputLdflags, compress, logOut, logErr)
+
+				logOut.Flush()
+				logErr.Flush()
+
+				if err != nil {
+					errCh <- err
+				} else {
+					digestsCh <- digests
+				}
+			}
+		}()
+	}
+
+	for _, target := range targets {
+		targetCh <- target
+	}
+	close(targetCh)
+
+	wg.Wait()
+	close(errCh)
+	close(digestsCh)
+
+	var buildErrors []error
+	for err := range errCh {
+		buildErrors = append(buildErrors, err)
+	}
 
-		// split platform by `/` (and clean all whitespaces)
-		platformSpec := strings.Split(strings.ReplaceAll(platform, " ", ""), "/")
+	if len(buildErrors) > 0 {
+		for _, err := range buildErrors {
+			fmt.Println("Build failure:", err)
+		}
+		os.Exit(1)
+	}
 
-		// create a `map` of `kernel` and `arch`
-		platformMap := map[string]string{
-			"kernel": platformSpec[0],
-			"arch":   platformSpec[1],
+	// fold every platform's archive digests into a single SHA256SUMS manifest
+	// covering every archive produced by this run
+	allDigests := make(map[string]string)
+	for digests := range digestsCh {
+		for fileName, digestHex := range digests {
+			allDigests[fileName] = digestHex
 		}
+	}
+
+	workspaceDir := os.Getenv("GITHUB_WORKSPACE")
+	destDirPath := filepath.Join(workspaceDir, destDir)
 
-		// execute `build` function
-		build(packageName, destDir, platformMap, inputLdflags, compress)
+	if err := writeSHA256Sums(destDirPath, allDigests); err != nil {
+		fmt.Println("An error occurred while writing the SHA256SUMS manifest:", err)
+		os.Exit(1)
+	}
+
+	// optionally upload every archive (plus its checksum sidecars) to an
+	// S3-compatible bucket, when `INPUT_UPLOAD_BUCKET` is set
+	if upload.Enabled() {
+		if err := uploadArtifacts(destDirPath, allDigests); err != nil {
+			fmt.Println("An error occurred while uploading artifacts:", err)
+			os.Exit(1)
+		}
 	}
 
 	/*------------*/
@@ -233,7 +520,7 @@ func main() {
 
 	var files []string
 
-	err := filepath.Walk(destDir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(destDir, func(path string, info os.FileInfo, err error) error {
 		files = append(files, path)
 		return nil
 	})
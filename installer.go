@@ -0,0 +1,193 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+/*************************************/
+
+//go:embed templates/installer.wxs.tmpl
+var msiTemplateSource string
+
+// whether `INPUT_INSTALLER` requests opt-in OS-native installer generation
+func installerEnabled() bool {
+	return strings.ToLower(strings.TrimSpace(os.Getenv("INPUT_INSTALLER"))) == "true"
+}
+
+// buildInstaller produces an OS-native installer for `target`, when
+// INPUT_INSTALLER=true and the platform is one that has an installer format
+// (darwin => .pkg, windows => .msi). Every other platform is a no-op.
+func buildInstaller(target Target, inputName, buildFilePath, destDirPath string, logOut, logErr io.Writer) error {
+
+	if !installerEnabled() {
+		return nil
+	}
+
+	logPrefix := target.String()
+
+	switch target.GOOS {
+	case "darwin":
+		return buildMacInstaller(target, inputName, buildFilePath, destDirPath, logPrefix, logOut, logErr)
+	case "windows":
+		return buildWindowsInstaller(target, inputName, buildFilePath, destDirPath, logPrefix, logOut, logErr)
+	default:
+		return nil
+	}
+}
+
+// buildMacInstaller wraps the binary into a `.pkg` using `pkgbuild`, taking
+// the package identifier and install location from INPUT_PKG_IDENTIFIER /
+// INPUT_PKG_INSTALL_LOCATION (falling back to sensible defaults). It's a
+// no-op (with a log line) when `pkgbuild` isn't available on the runner.
+func buildMacInstaller(target Target, inputName, buildFilePath, destDirPath, logPrefix string, logOut, logErr io.Writer) error {
+
+	if _, err := exec.LookPath("pkgbuild"); err != nil {
+		fmt.Fprintf(logOut, "[%s] pkgbuild not found, skipping .pkg installer\n", logPrefix)
+		return nil
+	}
+
+	identifier := strings.TrimSpace(os.Getenv("INPUT_PKG_IDENTIFIER"))
+	if identifier == "" {
+		identifier = fmt.Sprintf("com.github.actions.%s", inputName)
+	}
+
+	installLocation := strings.TrimSpace(os.Getenv("INPUT_PKG_INSTALL_LOCATION"))
+	if installLocation == "" {
+		installLocation = "/usr/local/bin"
+	}
+
+	// stage the binary under a package root mirroring `installLocation`, since
+	// `pkgbuild --root` packages a directory tree rather than a single file
+	pkgRoot := filepath.Join(destDirPath, fmt.Sprintf("pkgroot-%s", target.Slug()))
+	stagedBinaryDir := filepath.Join(pkgRoot, installLocation)
+	if err := os.MkdirAll(stagedBinaryDir, 0755); err != nil {
+		return fmt.Errorf("unable to create pkg root: %w", err)
+	}
+	defer os.RemoveAll(pkgRoot)
+
+	if err := copyFile(buildFilePath, filepath.Join(stagedBinaryDir, inputName)); err != nil {
+		return err
+	}
+
+	pkgFileName := fmt.Sprintf("%s-%s.pkg", inputName, target.Slug())
+	pkgPath := filepath.Join(destDirPath, pkgFileName)
+
+	pkgbuildCmd := exec.Command("pkgbuild",
+		"--root", pkgRoot,
+		"--identifier", identifier,
+		"--install-location", "/",
+		pkgPath,
+	)
+	pkgbuildCmd.Stdout = logOut
+	pkgbuildCmd.Stderr = logErr
+
+	fmt.Fprintf(logOut, "[%s] Building macOS installer using: %s\n", logPrefix, pkgbuildCmd.String())
+	if err := pkgbuildCmd.Run(); err != nil {
+		return fmt.Errorf("pkgbuild failed: %w", err)
+	}
+
+	return nil
+}
+
+// msiTemplateData is the set of values substituted into templates/installer.wxs.tmpl
+type msiTemplateData struct {
+	ProductName  string
+	Version      string
+	Manufacturer string
+	UpgradeGUID  string
+	SourceFile   string
+}
+
+// buildWindowsInstaller renders the embedded WiX template and drives
+// `candle`/`light` to produce a `.msi`, taking product name, upgrade GUID and
+// version from INPUT_MSI_PRODUCT_NAME / INPUT_MSI_UPGRADE_GUID /
+// INPUT_MSI_VERSION. It's a no-op (with a log line) when the WiX toolset
+// isn't available on the runner.
+func buildWindowsInstaller(target Target, inputName, buildFilePath, destDirPath, logPrefix string, logOut, logErr io.Writer) error {
+
+	if _, err := exec.LookPath("candle"); err != nil {
+		fmt.Fprintf(logOut, "[%s] WiX toolset (candle) not found, skipping .msi installer\n", logPrefix)
+		return nil
+	}
+	if _, err := exec.LookPath("light"); err != nil {
+		fmt.Fprintf(logOut, "[%s] WiX toolset (light) not found, skipping .msi installer\n", logPrefix)
+		return nil
+	}
+
+	upgradeGUID := strings.TrimSpace(os.Getenv("INPUT_MSI_UPGRADE_GUID"))
+	if upgradeGUID == "" {
+		return fmt.Errorf("INPUT_MSI_UPGRADE_GUID is required to build a .msi installer")
+	}
+
+	productName := strings.TrimSpace(os.Getenv("INPUT_MSI_PRODUCT_NAME"))
+	if productName == "" {
+		productName = inputName
+	}
+
+	version := strings.TrimSpace(os.Getenv("INPUT_MSI_VERSION"))
+	if version == "" {
+		version = "1.0.0"
+	}
+
+	manufacturer := strings.TrimSpace(os.Getenv("INPUT_MSI_MANUFACTURER"))
+	if manufacturer == "" {
+		manufacturer = productName
+	}
+
+	msiTemplate, err := template.New("installer.wxs").Parse(msiTemplateSource)
+	if err != nil {
+		return fmt.Errorf("unable to parse installer.wxs template: %w", err)
+	}
+
+	wxsPath := filepath.Join(destDirPath, fmt.Sprintf("%s-%s.wxs", inputName, target.Slug()))
+	wxsFile, err := os.Create(wxsPath)
+	if err != nil {
+		return fmt.Errorf("unable to create .wxs file: %w", err)
+	}
+	defer os.Remove(wxsPath)
+
+	err = msiTemplate.Execute(wxsFile, msiTemplateData{
+		ProductName:  productName,
+		Version:      version,
+		Manufacturer: manufacturer,
+		UpgradeGUID:  upgradeGUID,
+		SourceFile:   buildFilePath,
+	})
+	wxsFile.Close()
+	if err != nil {
+		return fmt.Errorf("unable to render .wxs template: %w", err)
+	}
+
+	wixobjPath := filepath.Join(destDirPath, fmt.Sprintf("%s-%s.wixobj", inputName, target.Slug()))
+	defer os.Remove(wixobjPath)
+
+	candleCmd := exec.Command("candle", "-out", wixobjPath, wxsPath)
+	candleCmd.Stdout = logOut
+	candleCmd.Stderr = logErr
+
+	fmt.Fprintf(logOut, "[%s] Compiling WiX source using: %s\n", logPrefix, candleCmd.String())
+	if err := candleCmd.Run(); err != nil {
+		return fmt.Errorf("candle failed: %w", err)
+	}
+
+	msiFileName := fmt.Sprintf("%s-%s.msi", inputName, target.Slug())
+	msiPath := filepath.Join(destDirPath, msiFileName)
+
+	lightCmd := exec.Command("light", "-out", msiPath, wixobjPath)
+	lightCmd.Stdout = logOut
+	lightCmd.Stderr = logErr
+
+	fmt.Fprintf(logOut, "[%s] Linking MSI using: %s\n", logPrefix, lightCmd.String())
+	if err := lightCmd.Run(); err != nil {
+		return fmt.Errorf("light failed: %w", err)
+	}
+
+	return nil
+}
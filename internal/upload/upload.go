@@ -0,0 +1,210 @@
+// Package upload implements the optional post-build artifact upload step:
+// pushing every archive (and its checksum sidecars) produced by a run to an
+// S3-compatible object store, and writing a `release.json` index describing
+// where each one ended up.
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Config holds the upload destination, resolved from INPUT_UPLOAD_* env vars
+type Config struct {
+	Bucket      string
+	Endpoint    string
+	Region      string
+	KeyTemplate string
+}
+
+// Artifact is a single local file to be uploaded (an archive or a checksum
+// sidecar). SHA256 is optional and is only known for the archive itself -
+// it's folded into the release.json index when present.
+type Artifact struct {
+	Path   string
+	SHA256 string
+}
+
+// Uploaded describes a single artifact once it has been uploaded, as
+// recorded in the release.json index
+type Uploaded struct {
+	FileName string `json:"file_name"`
+	Key      string `json:"key"`
+	URL      string `json:"url"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256,omitempty"`
+}
+
+// Enabled reports whether `INPUT_UPLOAD_BUCKET` requests the upload step
+func Enabled() bool {
+	return strings.TrimSpace(os.Getenv("INPUT_UPLOAD_BUCKET")) != ""
+}
+
+// ConfigFromEnv reads the upload destination from INPUT_UPLOAD_* env vars.
+// Credentials are left to the AWS SDK's default credential chain (standard
+// AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN env vars,
+// shared config, etc.) rather than being read here.
+func ConfigFromEnv() Config {
+
+	keyTemplate := strings.TrimSpace(os.Getenv("INPUT_UPLOAD_KEY_TEMPLATE"))
+	if keyTemplate == "" {
+		keyTemplate = "{project}/{version}/{filename}"
+	}
+
+	return Config{
+		Bucket:      strings.TrimSpace(os.Getenv("INPUT_UPLOAD_BUCKET")),
+		Endpoint:    strings.TrimSpace(os.Getenv("INPUT_UPLOAD_ENDPOINT")),
+		Region:      strings.TrimSpace(os.Getenv("INPUT_UPLOAD_REGION")),
+		KeyTemplate: keyTemplate,
+	}
+}
+
+// Run uploads every artifact to the configured S3-compatible bucket and
+// writes a combined release.json index alongside the build output in
+// destDirPath
+func Run(ctx context.Context, cfg Config, project, version string, artifacts []Artifact, destDirPath string, logOut io.Writer) error {
+
+	client, err := newClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("unable to configure upload client: %w", err)
+	}
+
+	uploaded := make([]Uploaded, 0, len(artifacts))
+
+	for _, artifact := range artifacts {
+		result, err := uploadOne(ctx, client, cfg, project, version, artifact, logOut)
+		if err != nil {
+			return err
+		}
+		uploaded = append(uploaded, result)
+	}
+
+	return writeReleaseIndex(destDirPath, uploaded)
+}
+
+// newClient builds an S3 client from the default AWS credential chain,
+// pointed at a custom endpoint (and forced to path-style addressing) when
+// `cfg.Endpoint` names an S3-compatible service other than AWS itself
+func newClient(ctx context.Context, cfg Config) (*s3.Client, error) {
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(cfg.Region))
+	if err != nil {
+		return nil, err
+	}
+
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+			o.UsePathStyle = true // required by MinIO/R2/B2 and most other S3-compatible services
+		}
+	}), nil
+}
+
+func uploadOne(ctx context.Context, client *s3.Client, cfg Config, project, version string, artifact Artifact, logOut io.Writer) (Uploaded, error) {
+
+	file, err := os.Open(artifact.Path)
+	if err != nil {
+		return Uploaded{}, fmt.Errorf("unable to open %s for upload: %w", artifact.Path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return Uploaded{}, fmt.Errorf("unable to stat %s: %w", artifact.Path, err)
+	}
+
+	fileName := filepath.Base(artifact.Path)
+	key := renderKey(cfg.KeyTemplate, project, version, fileName)
+
+	fmt.Fprintf(logOut, "Uploading %s to s3://%s/%s\n", fileName, cfg.Bucket, key)
+
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(cfg.Bucket),
+		Key:         aws.String(key),
+		Body:        file,
+		ContentType: aws.String(contentTypeFor(fileName)),
+	})
+	if err != nil {
+		return Uploaded{}, fmt.Errorf("unable to upload %s: %w", fileName, err)
+	}
+
+	return Uploaded{
+		FileName: fileName,
+		Key:      key,
+		URL:      publicURL(cfg, key),
+		Size:     info.Size(),
+		SHA256:   artifact.SHA256,
+	}, nil
+}
+
+// renderKey substitutes {project}/{version}/{filename} placeholders in
+// `template`
+func renderKey(template, project, version, fileName string) string {
+	key := strings.NewReplacer(
+		"{project}", project,
+		"{version}", version,
+		"{filename}", fileName,
+	).Replace(template)
+	return path.Clean(key)
+}
+
+// contentTypeFor picks a Content-Type for the well-known file extensions this
+// action produces, falling back to the standard mime package and finally to
+// a generic binary type
+func contentTypeFor(fileName string) string {
+	switch {
+	case strings.HasSuffix(fileName, ".tar.gz"):
+		return "application/gzip"
+	case strings.HasSuffix(fileName, ".zip"):
+		return "application/zip"
+	case strings.HasSuffix(fileName, ".sha256"), strings.HasSuffix(fileName, ".md5"):
+		return "text/plain; charset=utf-8"
+	case strings.HasSuffix(fileName, ".json"):
+		return "application/json"
+	default:
+		if ct := mime.TypeByExtension(filepath.Ext(fileName)); ct != "" {
+			return ct
+		}
+		return "application/octet-stream"
+	}
+}
+
+// publicURL derives the URL an uploaded object is reachable at: the custom
+// endpoint when one was configured, otherwise the standard AWS S3 URL shape
+func publicURL(cfg Config, key string) string {
+	if cfg.Endpoint != "" {
+		return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(cfg.Endpoint, "/"), cfg.Bucket, key)
+	}
+	if cfg.Region == "" || cfg.Region == "us-east-1" {
+		return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", cfg.Bucket, key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", cfg.Bucket, cfg.Region, key)
+}
+
+// writeReleaseIndex writes `release.json`, listing every uploaded artifact
+// with its size, sha256 and public URL
+func writeReleaseIndex(destDirPath string, uploaded []Uploaded) error {
+
+	data, err := json.MarshalIndent(uploaded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal release.json: %w", err)
+	}
+
+	path := filepath.Join(destDirPath, "release.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("unable to write release.json: %w", err)
+	}
+
+	return nil
+}
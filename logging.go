@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+/*************************************/
+
+// prefixWriter wraps an io.Writer and prepends `prefix` to every line written
+// to it, so interleaved output from concurrent subprocesses stays readable
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+// newPrefixWriter builds a prefixWriter that serializes writes through `mu`,
+// so multiple prefixWriters can safely share the same underlying `out`
+func newPrefixWriter(prefix string, out io.Writer, mu *sync.Mutex) *prefixWriter {
+	return &prefixWriter{prefix: prefix, out: out, mu: mu}
+}
+
+// Write buffers partial lines and flushes complete ones with the prefix
+// prepended, so a line split across multiple Write calls is only ever
+// printed once it's whole
+func (w *prefixWriter) Write(p []byte) (int, error) {
+
+	w.buf.Write(p)
+
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// incomplete line: push it back and wait for more input
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+
+		w.mu.Lock()
+		fmt.Fprintf(w.out, "[%s] %s", w.prefix, line)
+		w.mu.Unlock()
+	}
+
+	return len(p), nil
+}
+
+// Flush writes out any remaining buffered partial line
+func (w *prefixWriter) Flush() {
+	if w.buf.Len() == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	fmt.Fprintf(w.out, "[%s] %s\n", w.prefix, w.buf.String())
+	w.mu.Unlock()
+
+	w.buf.Reset()
+}
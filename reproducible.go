@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*************************************/
+
+// whether `INPUT_REPRODUCIBLE` requests reproducible-build mode
+func reproducibleBuildEnabled() bool {
+	return strings.ToLower(strings.TrimSpace(os.Getenv("INPUT_REPRODUCIBLE"))) == "true"
+}
+
+// resolve the timestamp reproducible-build mode pins every archive entry's
+// modification time to. Honors a pre-existing `SOURCE_DATE_EPOCH` (as set by
+// an enclosing reproducible-build pipeline); otherwise mints one from the
+// current time so every platform built by this run shares the same epoch.
+func resolveSourceDateEpoch() time.Time {
+
+	raw := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+	if raw == "" {
+		return time.Now().UTC()
+	}
+
+	epoch, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		fmt.Println("Invalid SOURCE_DATE_EPOCH value, falling back to the current time:", raw)
+		return time.Now().UTC()
+	}
+
+	return time.Unix(epoch, 0).UTC()
+}
+
+// reproducibleLdflags appends the flags needed to make the build itself
+// reproducible (a stable, empty build ID and stripped debug/symbol tables) to
+// the user-supplied ldflags
+func reproducibleLdflags(ldflags string) string {
+	return strings.TrimSpace(ldflags + " -buildid= -s -w")
+}